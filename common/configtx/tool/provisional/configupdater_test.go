@@ -0,0 +1,218 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisional
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	configvaluesmsp "github.com/hyperledger/fabric/common/config/msp"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+func mockConfigWithConsortium(name string) *cb.Config {
+	return &cb.Config{
+		ChannelGroup: &cb.ConfigGroup{
+			Groups: map[string]*cb.ConfigGroup{
+				config.ConsortiumsGroupKey: {
+					Version: 0,
+					Groups: map[string]*cb.ConfigGroup{
+						name: {
+							Version: 0,
+							Groups:  map[string]*cb.ConfigGroup{},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAddConsortiumAlreadyExists(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := mockConfigWithConsortium("SampleConsortium")
+
+	_, err := cu.AddConsortium(current, "SampleConsortium", nil)
+	if err == nil {
+		t.Fatalf("expected an error for a consortium which already exists")
+	}
+}
+
+func TestAddConsortiumNoConsortiumsGroup(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := &cb.Config{ChannelGroup: &cb.ConfigGroup{}}
+
+	_, err := cu.AddConsortium(current, "SampleConsortium", nil)
+	if err == nil {
+		t.Fatalf("expected an error when the current config has no consortiums group")
+	}
+}
+
+func TestAddOrgToConsortiumUnknownConsortium(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := mockConfigWithConsortium("SampleConsortium")
+
+	_, err := cu.AddOrgToConsortium(current, "NotAConsortium", &genesisconfig.Organization{Name: "Org1"})
+	if err == nil {
+		t.Fatalf("expected an error for a consortium that does not exist")
+	}
+}
+
+func TestAddOrgToConsortiumAlreadyMember(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := mockConfigWithConsortium("SampleConsortium")
+	current.ChannelGroup.Groups[config.ConsortiumsGroupKey].Groups["SampleConsortium"].Groups["Org1"] = &cb.ConfigGroup{}
+
+	_, err := cu.AddOrgToConsortium(current, "SampleConsortium", &genesisconfig.Organization{Name: "Org1"})
+	if err == nil {
+		t.Fatalf("expected an error when org is already a member of the consortium")
+	}
+}
+
+func TestUpdateOrgMSPUnknownPath(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := mockConfigWithConsortium("SampleConsortium")
+
+	_, err := cu.UpdateOrgMSP(current, []string{config.ConsortiumsGroupKey, "NotAConsortium"}, &genesisconfig.Organization{Name: "Org1"})
+	if err == nil {
+		t.Fatalf("expected an error for a path that does not exist in the current config")
+	}
+}
+
+func TestUpdateOrgMSPUnknownOrg(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := mockConfigWithConsortium("SampleConsortium")
+
+	_, err := cu.UpdateOrgMSP(current, []string{config.ConsortiumsGroupKey, "SampleConsortium"}, &genesisconfig.Organization{Name: "Org1"})
+	if err == nil {
+		t.Fatalf("expected an error for an org that does not exist at the given path")
+	}
+}
+
+func TestAddConsortiumSuccess(t *testing.T) {
+	cu := NewConfigUpdater("testchainid")
+	current := mockConfigWithConsortium("SampleConsortium")
+
+	env, err := cu.AddConsortium(current, "NewConsortium", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(env.ConfigUpdate, configUpdate); err != nil {
+		t.Fatalf("unexpected error unmarshaling config update: %s", err)
+	}
+
+	consortiumsGroup := configUpdate.WriteSet.Groups[config.ConsortiumsGroupKey]
+	if consortiumsGroup == nil {
+		t.Fatalf("expected a consortiums group in the write set")
+	}
+	if _, ok := consortiumsGroup.Groups["NewConsortium"]; !ok {
+		t.Fatalf("expected the new consortium to be present in the write set")
+	}
+	if _, ok := consortiumsGroup.Groups["SampleConsortium"]; !ok {
+		t.Fatalf("expected the pre-existing consortium to survive the update")
+	}
+}
+
+func TestMergeConfigGroupBumpsVersionAndModPolicy(t *testing.T) {
+	dst := &cb.ConfigGroup{
+		Version: 3,
+		Values:  map[string]*cb.ConfigValue{"Existing": {Value: []byte("unchanged")}},
+		Groups:  map[string]*cb.ConfigGroup{},
+	}
+	delta := &cb.ConfigGroup{
+		Values: map[string]*cb.ConfigValue{"New": {Value: []byte("added")}},
+	}
+
+	if err := mergeConfigGroup(dst, delta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if dst.Version != 4 {
+		t.Fatalf("expected version to be bumped to 4, got %d", dst.Version)
+	}
+	if dst.ModPolicy != configvaluesmsp.AdminsPolicyKey {
+		t.Fatalf("expected mod_policy to be set to Admins, got %s", dst.ModPolicy)
+	}
+	if _, ok := dst.Values["Existing"]; !ok {
+		t.Fatalf("expected pre-existing value to be preserved")
+	}
+	if _, ok := dst.Values["New"]; !ok {
+		t.Fatalf("expected new value to be merged in")
+	}
+}
+
+func TestMergeConfigGroupLeavesUntouchedGroupsAlone(t *testing.T) {
+	dst := &cb.ConfigGroup{
+		Version: 1,
+		Groups: map[string]*cb.ConfigGroup{
+			"Org1": {Version: 5},
+		},
+	}
+
+	if err := mergeConfigGroup(dst, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if dst.Groups["Org1"].Version != 5 {
+		t.Fatalf("expected untouched sub-group to be left alone, got version %d", dst.Groups["Org1"].Version)
+	}
+}
+
+// TestMergeConfigGroupPreservesAncestorModPolicy ensures that reaching a new, nested group
+// through unchanged ancestors does not force those ancestors' version/mod_policy to bump, which
+// would silently overwrite any custom mod_policy an operator had set on them.
+func TestMergeConfigGroupPreservesAncestorModPolicy(t *testing.T) {
+	dst := &cb.ConfigGroup{
+		Version:   1,
+		ModPolicy: "RootCustomPolicy",
+		Groups: map[string]*cb.ConfigGroup{
+			config.ConsortiumsGroupKey: {
+				Version:   7,
+				ModPolicy: "ConsortiumsCustomPolicy",
+				Groups:    map[string]*cb.ConfigGroup{},
+			},
+		},
+	}
+	delta := &cb.ConfigGroup{
+		Groups: map[string]*cb.ConfigGroup{
+			config.ConsortiumsGroupKey: {
+				Groups: map[string]*cb.ConfigGroup{
+					"NewConsortium": {Version: 0, ModPolicy: configvaluesmsp.AdminsPolicyKey},
+				},
+			},
+		},
+	}
+
+	if err := mergeConfigGroup(dst, delta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if dst.Version != 1 || dst.ModPolicy != "RootCustomPolicy" {
+		t.Fatalf("expected root group to be untouched, got version %d mod_policy %s", dst.Version, dst.ModPolicy)
+	}
+	consortiumsGroup := dst.Groups[config.ConsortiumsGroupKey]
+	if consortiumsGroup.Version != 7 || consortiumsGroup.ModPolicy != "ConsortiumsCustomPolicy" {
+		t.Fatalf("expected consortiums group to be untouched, got version %d mod_policy %s", consortiumsGroup.Version, consortiumsGroup.ModPolicy)
+	}
+	if _, ok := consortiumsGroup.Groups["NewConsortium"]; !ok {
+		t.Fatalf("expected the new consortium group to be merged in")
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisional
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+func TestInspectBlockNilBlock(t *testing.T) {
+	if _, err := InspectBlock(nil); err == nil {
+		t.Fatalf("expected an error for a nil block")
+	}
+}
+
+func TestInspectBlockNilData(t *testing.T) {
+	if _, err := InspectBlock(&cb.Block{}); err == nil {
+		t.Fatalf("expected an error for a block with a nil Data")
+	}
+}
+
+func TestInspectBlockEmptyData(t *testing.T) {
+	block := &cb.Block{Data: &cb.BlockData{}}
+	if _, err := InspectBlock(block); err == nil {
+		t.Fatalf("expected an error for a block with no data")
+	}
+}
+
+func TestInspectChannelCreateTxNilEnvelope(t *testing.T) {
+	if _, err := InspectChannelCreateTx(nil); err == nil {
+		t.Fatalf("expected an error for a nil envelope")
+	}
+}
+
+func TestOrdererFromGroupParsesBatchTimeout(t *testing.T) {
+	batchTimeout := &ab.BatchTimeout{Timeout: "2s"}
+	ordererGroup := &cb.ConfigGroup{
+		Values: map[string]*cb.ConfigValue{
+			config.BatchTimeoutKey: {Value: marshalOrFail(t, batchTimeout)},
+		},
+	}
+
+	orderer, err := ordererFromGroup(ordererGroup)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if orderer.BatchTimeout != 2*time.Second {
+		t.Fatalf("expected a batch timeout of 2s, got %s", orderer.BatchTimeout)
+	}
+}
+
+func TestOrdererFromGroupRejectsUnparseableBatchTimeout(t *testing.T) {
+	batchTimeout := &ab.BatchTimeout{Timeout: "not-a-duration"}
+	ordererGroup := &cb.ConfigGroup{
+		Values: map[string]*cb.ConfigValue{
+			config.BatchTimeoutKey: {Value: marshalOrFail(t, batchTimeout)},
+		},
+	}
+
+	if _, err := ordererFromGroup(ordererGroup); err == nil {
+		t.Fatalf("expected an error for an unparseable batch timeout")
+	}
+}
+
+func TestProfileFromChannelGroupRecoversOrdererAddresses(t *testing.T) {
+	addresses := &cb.OrdererAddresses{Addresses: []string{"orderer0:7050", "orderer1:7050"}}
+	channelGroup := &cb.ConfigGroup{
+		Values: map[string]*cb.ConfigValue{
+			config.OrdererAddressesKey: {Value: marshalOrFail(t, addresses)},
+		},
+		Groups: map[string]*cb.ConfigGroup{
+			config.OrdererGroupKey: {},
+		},
+	}
+
+	profile, err := profileFromChannelGroup(channelGroup)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(profile.Orderer.Addresses) != 2 || profile.Orderer.Addresses[0] != "orderer0:7050" {
+		t.Fatalf("expected orderer addresses to round-trip, got %v", profile.Orderer.Addresses)
+	}
+}
+
+// TestGenesisBlockRoundTrip generates a genesis block from a profile with no organizations (so
+// it needs no MSP directory fixtures), decodes it back with InspectBlock, and asserts the
+// recovered profile matches the fields the original profile set.
+func TestGenesisBlockRoundTrip(t *testing.T) {
+	profile := &genesisconfig.Profile{
+		Orderer: &genesisconfig.Orderer{},
+		Consortiums: map[string]*genesisconfig.Consortium{
+			"SampleConsortium": {},
+		},
+	}
+	profile.Orderer.OrdererType = ConsensusTypeSolo
+	profile.Orderer.Addresses = []string{"orderer0:7050"}
+	profile.Orderer.BatchTimeout = 2 * time.Second
+	profile.Orderer.BatchSize.MaxMessageCount = 10
+	profile.Orderer.BatchSize.AbsoluteMaxBytes = 100
+	profile.Orderer.BatchSize.PreferredMaxBytes = 90
+	profile.Orderer.MaxChannels = 1000
+
+	block := New(profile).GenesisBlock()
+
+	inspected, err := InspectBlock(block)
+	if err != nil {
+		t.Fatalf("unexpected error inspecting genesis block: %s", err)
+	}
+
+	if inspected.Orderer.OrdererType != profile.Orderer.OrdererType {
+		t.Fatalf("expected orderer type %s, got %s", profile.Orderer.OrdererType, inspected.Orderer.OrdererType)
+	}
+	if !reflect.DeepEqual(inspected.Orderer.Addresses, profile.Orderer.Addresses) {
+		t.Fatalf("expected orderer addresses %v, got %v", profile.Orderer.Addresses, inspected.Orderer.Addresses)
+	}
+	if inspected.Orderer.BatchTimeout != profile.Orderer.BatchTimeout {
+		t.Fatalf("expected batch timeout %s, got %s", profile.Orderer.BatchTimeout, inspected.Orderer.BatchTimeout)
+	}
+	if inspected.Orderer.BatchSize != profile.Orderer.BatchSize {
+		t.Fatalf("expected batch size %+v, got %+v", profile.Orderer.BatchSize, inspected.Orderer.BatchSize)
+	}
+	if inspected.Orderer.MaxChannels != profile.Orderer.MaxChannels {
+		t.Fatalf("expected max channels %d, got %d", profile.Orderer.MaxChannels, inspected.Orderer.MaxChannels)
+	}
+	if _, ok := inspected.Consortiums["SampleConsortium"]; !ok {
+		t.Fatalf("expected consortium SampleConsortium to round-trip")
+	}
+}
+
+func marshalOrFail(t *testing.T, msg proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %s", err)
+	}
+	return data
+}
@@ -18,7 +18,9 @@ package provisional
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/config"
 	configvaluesmsp "github.com/hyperledger/fabric/common/config/msp"
 	"github.com/hyperledger/fabric/common/configtx"
@@ -31,6 +33,7 @@ import (
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
 )
 
@@ -58,6 +61,23 @@ type Generator interface {
 	GenesisBlockForChannel(channelID string) *cb.Block
 }
 
+// ChannelArtifactGenerator extends Generator with the ability to produce the application-channel
+// creation and anchor-peer update transactions that configtxgen's other two output modes need.
+// It is kept separate from Generator so that existing implementers of Generator elsewhere in the
+// tree are not broken by the addition of these methods.
+type ChannelArtifactGenerator interface {
+	Generator
+
+	// ChannelCreationTx creates a channel creation transaction for a new application
+	// channel with the given ID, to be submitted to the named consortium on the
+	// ordering service's system channel.
+	ChannelCreationTx(channelID, consortium string) (*cb.Envelope, error)
+
+	// AnchorPeersUpdate creates a config update transaction which sets the anchor
+	// peers for the given organization within the given channel.
+	AnchorPeersUpdate(channelID, orgName string) (*cb.Envelope, error)
+}
+
 const (
 	// ConsensusTypeSolo identifies the solo consensus implementation.
 	ConsensusTypeSolo = "solo"
@@ -74,16 +94,60 @@ const (
 	BlockValidationPolicyKey = "BlockValidation"
 )
 
+// ConsensusContributor is implemented by a consensus type to produce the ConfigGroups it
+// needs added to the orderer group of the genesis block (metadata, membership, TLS certs,
+// tick intervals, snapshot params, etc).
+type ConsensusContributor func(conf *genesisconfig.Orderer) []*cb.ConfigGroup
+
+var consensusTypes = map[string]ConsensusContributor{}
+
+// RegisterConsensusType registers a ConsensusContributor under the given orderer type name,
+// allowing New/NewWithError to produce the orderer-specific ConfigGroups for that type without
+// this package needing to know about it. It is intended to be called from the init() function
+// of a consensus implementation's package, and panics if name is already registered.
+func RegisterConsensusType(name string, contributor ConsensusContributor) {
+	if _, ok := consensusTypes[name]; ok {
+		panic(fmt.Errorf("consensus type %s is already registered", name))
+	}
+	consensusTypes[name] = contributor
+}
+
+func init() {
+	RegisterConsensusType(ConsensusTypeSolo, soloConsenterConfigGroups)
+	RegisterConsensusType(ConsensusTypeKafka, kafkaConsenterConfigGroups)
+}
+
+func soloConsenterConfigGroups(conf *genesisconfig.Orderer) []*cb.ConfigGroup {
+	return nil
+}
+
+func kafkaConsenterConfigGroups(conf *genesisconfig.Orderer) []*cb.ConfigGroup {
+	return []*cb.ConfigGroup{config.TemplateKafkaBrokers(conf.Kafka.Brokers)}
+}
+
 type bootstrapper struct {
+	conf              *genesisconfig.Profile
 	channelGroups     []*cb.ConfigGroup
 	ordererGroups     []*cb.ConfigGroup
 	applicationGroups []*cb.ConfigGroup
 	consortiumsGroups []*cb.ConfigGroup
 }
 
-// New returns a new provisional bootstrap helper.
-func New(conf *genesisconfig.Profile) Generator {
+// New returns a new provisional bootstrap helper. It panics if conf specifies an unknown
+// orderer consensus type; use NewWithError if that should instead be handled as an error.
+func New(conf *genesisconfig.Profile) ChannelArtifactGenerator {
+	bs, err := NewWithError(conf)
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// NewWithError returns a new provisional bootstrap helper, or an error if conf specifies an
+// orderer consensus type which has not been registered via RegisterConsensusType.
+func NewWithError(conf *genesisconfig.Profile) (ChannelArtifactGenerator, error) {
 	bs := &bootstrapper{
+		conf: conf,
 		channelGroups: []*cb.ConfigGroup{
 			// Chain Config Types
 			config.DefaultHashingAlgorithm(),
@@ -128,13 +192,11 @@ func New(conf *genesisconfig.Profile) Generator {
 			)
 		}
 
-		switch conf.Orderer.OrdererType {
-		case ConsensusTypeSolo:
-		case ConsensusTypeKafka:
-			bs.ordererGroups = append(bs.ordererGroups, config.TemplateKafkaBrokers(conf.Orderer.Kafka.Brokers))
-		default:
-			panic(fmt.Errorf("Wrong consenter type value given: %s", conf.Orderer.OrdererType))
+		contributor, ok := consensusTypes[conf.Orderer.OrdererType]
+		if !ok {
+			return nil, fmt.Errorf("wrong consenter type value given: %s", conf.Orderer.OrdererType)
 		}
+		bs.ordererGroups = append(bs.ordererGroups, contributor(conf.Orderer)...)
 	}
 
 	if conf.Application != nil {
@@ -195,7 +257,7 @@ func New(conf *genesisconfig.Profile) Generator {
 		}
 	}
 
-	return bs
+	return bs, nil
 }
 
 // ChannelTemplate TODO
@@ -245,3 +307,514 @@ func (bs *bootstrapper) GenesisBlockForChannel(channelID string) *cb.Block {
 	}
 	return block
 }
+
+// ChannelCreationTx creates a channel creation transaction for a new application channel with
+// the given ID, referencing the named consortium.  The returned envelope can be submitted to
+// the ordering service's system channel to create the new channel.
+func (bs *bootstrapper) ChannelCreationTx(channelID, consortium string) (*cb.Envelope, error) {
+	if channelID == "" {
+		return nil, fmt.Errorf("cannot create channel creation transaction without a channel ID")
+	}
+
+	if consortium == "" {
+		return nil, fmt.Errorf("cannot create channel creation transaction without a consortium")
+	}
+
+	if bs.conf.Consortiums == nil {
+		return nil, fmt.Errorf("cannot create channel creation transaction, no consortiums defined")
+	}
+
+	if _, ok := bs.conf.Consortiums[consortium]; !ok {
+		return nil, fmt.Errorf("consortium %s is not defined in the orderer profile", consortium)
+	}
+
+	configUpdateEnvelope, err := bs.ChannelTemplate().Envelope(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("error computing channel creation config update: %s", err)
+	}
+
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate); err != nil {
+		return nil, fmt.Errorf("error unmarshaling channel creation config update: %s", err)
+	}
+	configUpdate.WriteSet.Values[config.ConsortiumKey] = &cb.ConfigValue{
+		Value:     utils.MarshalOrPanic(&cb.Consortium{Name: consortium}),
+		ModPolicy: configvaluesmsp.AdminsPolicyKey,
+	}
+	configUpdateEnvelope.ConfigUpdate = utils.MarshalOrPanic(configUpdate)
+
+	return utils.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, channelID, nil, configUpdateEnvelope, 0, 0)
+}
+
+// AnchorPeersUpdate creates a config update transaction which sets the anchor peers for the
+// given organization within the given channel.  It returns an error if the organization has
+// no anchor peers configured in the profile used to build this Generator.
+func (bs *bootstrapper) AnchorPeersUpdate(channelID, orgName string) (*cb.Envelope, error) {
+	if channelID == "" {
+		return nil, fmt.Errorf("cannot create anchor peer update without a channel ID")
+	}
+
+	if bs.conf.Application == nil {
+		return nil, fmt.Errorf("cannot create anchor peer update, no application section defined")
+	}
+
+	var org *genesisconfig.Organization
+	for _, candidate := range bs.conf.Application.Organizations {
+		if candidate.Name == orgName {
+			org = candidate
+			break
+		}
+	}
+	if org == nil {
+		return nil, fmt.Errorf("org %s is not defined in the application profile", orgName)
+	}
+	if len(org.AnchorPeers) == 0 {
+		return nil, fmt.Errorf("org %s has no anchor peers defined", orgName)
+	}
+
+	var anchorProtos []*pb.AnchorPeer
+	for _, anchorPeer := range org.AnchorPeers {
+		anchorProtos = append(anchorProtos, &pb.AnchorPeer{
+			Host: anchorPeer.Host,
+			Port: int32(anchorPeer.Port),
+		})
+	}
+
+	template := configtx.NewModPolicySettingTemplate(
+		configvaluesmsp.AdminsPolicyKey,
+		configtx.NewSimpleTemplate(config.TemplateAnchorPeers(org.Name, anchorProtos)),
+	)
+
+	configUpdateEnvelope, err := template.Envelope(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("error computing anchor peer update: %s", err)
+	}
+
+	return utils.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, channelID, nil, configUpdateEnvelope, 0, 0)
+}
+
+// InspectBlock decodes a genesis block produced by this package back into the Profile which
+// produced it, mirroring what configtxgen's -inspectBlock flag prints today. It is the
+// approximate inverse of New(profile).GenesisBlock().
+func InspectBlock(block *cb.Block) (*genesisconfig.Profile, error) {
+	if block == nil || block.Data == nil || len(block.Data.Data) == 0 {
+		return nil, fmt.Errorf("block is empty")
+	}
+
+	envelope, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting envelope from block: %s", err)
+	}
+
+	payload, err := utils.ExtractPayload(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting payload from envelope: %s", err)
+	}
+
+	configEnvelope := &cb.ConfigEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config envelope: %s", err)
+	}
+
+	return profileFromChannelGroup(configEnvelope.Config.ChannelGroup)
+}
+
+// InspectChannelCreateTx decodes a channel creation transaction produced by ChannelCreationTx
+// back into the Profile which produced it, mirroring configtxgen's -inspectChannelCreateTx flag.
+func InspectChannelCreateTx(env *cb.Envelope) (*genesisconfig.Profile, error) {
+	if env == nil {
+		return nil, fmt.Errorf("envelope is empty")
+	}
+
+	payload, err := utils.ExtractPayload(env)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting payload from envelope: %s", err)
+	}
+
+	configUpdateEnvelope := &cb.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configUpdateEnvelope); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config update envelope: %s", err)
+	}
+
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config update: %s", err)
+	}
+
+	// A channel creation tx's write set holds the entire initial channel config, so it can be
+	// walked exactly like a genesis block's channel group.
+	return profileFromChannelGroup(configUpdate.WriteSet)
+}
+
+// profileFromChannelGroup walks a channel ConfigGroup tree (as found in either a genesis block
+// or a channel creation transaction) and reconstructs the Profile that would produce it.
+func profileFromChannelGroup(channelGroup *cb.ConfigGroup) (*genesisconfig.Profile, error) {
+	if channelGroup == nil {
+		return nil, fmt.Errorf("channel group is empty")
+	}
+
+	profile := &genesisconfig.Profile{}
+
+	if ordererGroup, ok := channelGroup.Groups[config.OrdererGroupKey]; ok {
+		orderer, err := ordererFromGroup(ordererGroup)
+		if err != nil {
+			return nil, fmt.Errorf("error inspecting orderer group: %s", err)
+		}
+
+		// OrdererAddresses is stored as a channel-level value (see config.TemplateOrdererAddresses
+		// in New), not inside the orderer group itself.
+		if value, ok := channelGroup.Values[config.OrdererAddressesKey]; ok {
+			ordererAddresses := &cb.OrdererAddresses{}
+			if err := proto.Unmarshal(value.Value, ordererAddresses); err != nil {
+				return nil, fmt.Errorf("error unmarshaling orderer addresses: %s", err)
+			}
+			orderer.Addresses = ordererAddresses.Addresses
+		}
+
+		profile.Orderer = orderer
+	}
+
+	if applicationGroup, ok := channelGroup.Groups[config.ApplicationGroupKey]; ok {
+		application, err := applicationFromGroup(applicationGroup)
+		if err != nil {
+			return nil, fmt.Errorf("error inspecting application group: %s", err)
+		}
+		profile.Application = application
+	}
+
+	if consortiumsGroup, ok := channelGroup.Groups[config.ConsortiumsGroupKey]; ok {
+		consortiums, err := consortiumsFromGroup(consortiumsGroup)
+		if err != nil {
+			return nil, fmt.Errorf("error inspecting consortiums group: %s", err)
+		}
+		profile.Consortiums = consortiums
+	}
+
+	return profile, nil
+}
+
+func ordererFromGroup(ordererGroup *cb.ConfigGroup) (*genesisconfig.Orderer, error) {
+	orderer := &genesisconfig.Orderer{}
+
+	if value, ok := ordererGroup.Values[config.ConsensusTypeKey]; ok {
+		consensusType := &ab.ConsensusType{}
+		if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+			return nil, fmt.Errorf("error unmarshaling consensus type: %s", err)
+		}
+		orderer.OrdererType = consensusType.Type
+	}
+
+	if value, ok := ordererGroup.Values[config.BatchSizeKey]; ok {
+		batchSize := &ab.BatchSize{}
+		if err := proto.Unmarshal(value.Value, batchSize); err != nil {
+			return nil, fmt.Errorf("error unmarshaling batch size: %s", err)
+		}
+		orderer.BatchSize.MaxMessageCount = batchSize.MaxMessageCount
+		orderer.BatchSize.AbsoluteMaxBytes = batchSize.AbsoluteMaxBytes
+		orderer.BatchSize.PreferredMaxBytes = batchSize.PreferredMaxBytes
+	}
+
+	if value, ok := ordererGroup.Values[config.BatchTimeoutKey]; ok {
+		batchTimeout := &ab.BatchTimeout{}
+		if err := proto.Unmarshal(value.Value, batchTimeout); err != nil {
+			return nil, fmt.Errorf("error unmarshaling batch timeout: %s", err)
+		}
+		d, err := time.ParseDuration(batchTimeout.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing batch timeout: %s", err)
+		}
+		orderer.BatchTimeout = d
+	}
+
+	if value, ok := ordererGroup.Values[config.ChannelRestrictionsKey]; ok {
+		channelRestrictions := &ab.ChannelRestrictions{}
+		if err := proto.Unmarshal(value.Value, channelRestrictions); err != nil {
+			return nil, fmt.Errorf("error unmarshaling channel restrictions: %s", err)
+		}
+		orderer.MaxChannels = channelRestrictions.MaxCount
+	}
+
+	if contributor, ok := consensusInspectors[orderer.OrdererType]; ok {
+		if err := contributor(ordererGroup, orderer); err != nil {
+			return nil, fmt.Errorf("error inspecting %s consensus metadata: %s", orderer.OrdererType, err)
+		}
+	}
+
+	for name, group := range ordererGroup.Groups {
+		org, err := organizationFromGroup(name, group)
+		if err != nil {
+			return nil, err
+		}
+		orderer.Organizations = append(orderer.Organizations, org)
+	}
+
+	return orderer, nil
+}
+
+// consensusInspectors recovers a consensus type's type-specific metadata in
+// InspectBlock/InspectChannelCreateTx. It is a fixed table holding only kafka; there is no function
+// to add entries to it, so consensus types without an entry (solo, which has no metadata, and any
+// type added with RegisterConsensusType) are simply skipped.
+var consensusInspectors = map[string]func(ordererGroup *cb.ConfigGroup, orderer *genesisconfig.Orderer) error{
+	ConsensusTypeKafka: func(ordererGroup *cb.ConfigGroup, orderer *genesisconfig.Orderer) error {
+		value, ok := ordererGroup.Values[config.KafkaBrokersKey]
+		if !ok {
+			return nil
+		}
+		kafkaBrokers := &ab.KafkaBrokers{}
+		if err := proto.Unmarshal(value.Value, kafkaBrokers); err != nil {
+			return fmt.Errorf("error unmarshaling kafka brokers: %s", err)
+		}
+		orderer.Kafka.Brokers = kafkaBrokers.Brokers
+		return nil
+	},
+}
+
+func applicationFromGroup(applicationGroup *cb.ConfigGroup) (*genesisconfig.Application, error) {
+	application := &genesisconfig.Application{}
+
+	for name, group := range applicationGroup.Groups {
+		org, err := organizationFromGroup(name, group)
+		if err != nil {
+			return nil, err
+		}
+
+		if value, ok := group.Values[config.AnchorPeersKey]; ok {
+			anchorPeers := &pb.AnchorPeers{}
+			if err := proto.Unmarshal(value.Value, anchorPeers); err != nil {
+				return nil, fmt.Errorf("error unmarshaling anchor peers for org %s: %s", name, err)
+			}
+			for _, anchorPeer := range anchorPeers.AnchorPeers {
+				org.AnchorPeers = append(org.AnchorPeers, &genesisconfig.AnchorPeer{
+					Host: anchorPeer.Host,
+					Port: int(anchorPeer.Port),
+				})
+			}
+		}
+
+		application.Organizations = append(application.Organizations, org)
+	}
+
+	return application, nil
+}
+
+func consortiumsFromGroup(consortiumsGroup *cb.ConfigGroup) (map[string]*genesisconfig.Consortium, error) {
+	consortiums := map[string]*genesisconfig.Consortium{}
+
+	for consortiumName, consortiumGroup := range consortiumsGroup.Groups {
+		consortium := &genesisconfig.Consortium{}
+		for orgName, orgGroup := range consortiumGroup.Groups {
+			org, err := organizationFromGroup(orgName, orgGroup)
+			if err != nil {
+				return nil, err
+			}
+			consortium.Organizations = append(consortium.Organizations, org)
+		}
+		consortiums[consortiumName] = consortium
+	}
+
+	return consortiums, nil
+}
+
+// organizationFromGroup extracts the parts of an Organization which survive being serialized
+// into an MSP ConfigGroup: its name and ID. The MSPDir/BCCSP fields cannot be recovered from a
+// channel config, since only the verifying MSP material (not its on-disk location) is persisted.
+func organizationFromGroup(name string, group *cb.ConfigGroup) (*genesisconfig.Organization, error) {
+	org := &genesisconfig.Organization{Name: name}
+
+	value, ok := group.Values[configvaluesmsp.MSPKey]
+	if !ok {
+		return org, nil
+	}
+
+	mspConfig := &msp.MSPConfig{}
+	if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+		return nil, fmt.Errorf("error unmarshaling MSP config for org %s: %s", name, err)
+	}
+
+	fabricMSPConfig := &msp.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricMSPConfig); err != nil {
+		return nil, fmt.Errorf("error unmarshaling fabric MSP config for org %s: %s", name, err)
+	}
+	org.ID = fabricMSPConfig.Name
+
+	return org, nil
+}
+
+// ConfigUpdater computes the ConfigUpdateEnvelopes needed to evolve a system channel's
+// consortium and organization membership after its genesis block has already been created and
+// the channel is live.
+type ConfigUpdater struct {
+	channelID string
+}
+
+// NewConfigUpdater returns a ConfigUpdater for the system channel identified by channelID.
+func NewConfigUpdater(channelID string) *ConfigUpdater {
+	return &ConfigUpdater{channelID: channelID}
+}
+
+// AddConsortium returns a ConfigUpdateEnvelope which adds a new consortium with the given name
+// and member organizations to current. It returns an error if the consortium already exists.
+func (cu *ConfigUpdater) AddConsortium(current *cb.Config, name string, orgs []*genesisconfig.Organization) (*cb.ConfigUpdateEnvelope, error) {
+	consortiumsGroup, ok := current.ChannelGroup.Groups[config.ConsortiumsGroupKey]
+	if !ok {
+		return nil, fmt.Errorf("current config has no consortiums group")
+	}
+	if _, ok := consortiumsGroup.Groups[name]; ok {
+		return nil, fmt.Errorf("consortium %s already exists", name)
+	}
+
+	groups := []*cb.ConfigGroup{
+		config.TemplateConsortiumChannelCreationPolicy(name, policies.ImplicitMetaPolicyWithSubPolicy(
+			configvaluesmsp.AdminsPolicyKey,
+			cb.ImplicitMetaPolicy_ANY,
+		).Policy),
+	}
+	for _, org := range orgs {
+		mspConfig, err := msp.GetVerifyingMspConfig(org.MSPDir, org.BCCSP, org.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading MSP configuration for org %s: %s", org.Name, err)
+		}
+		groups = append(groups, configvaluesmsp.TemplateGroupMSPWithAdminRolePrincipal(
+			[]string{config.ConsortiumsGroupKey, name, org.Name}, mspConfig,
+			org.AdminPrincipal == genesisconfig.AdminRoleAdminPrincipal,
+		))
+	}
+
+	template := configtx.NewModPolicySettingTemplate(configvaluesmsp.AdminsPolicyKey, configtx.NewSimpleTemplate(groups...))
+	return cu.configUpdateEnvelope(current, template)
+}
+
+// AddOrgToConsortium returns a ConfigUpdateEnvelope which adds org as a member of the named,
+// already-existing consortium. It returns an error if the consortium does not exist or already
+// has a member with the same name.
+func (cu *ConfigUpdater) AddOrgToConsortium(current *cb.Config, consortium string, org *genesisconfig.Organization) (*cb.ConfigUpdateEnvelope, error) {
+	consortiumsGroup, ok := current.ChannelGroup.Groups[config.ConsortiumsGroupKey]
+	if !ok {
+		return nil, fmt.Errorf("current config has no consortiums group")
+	}
+	consortiumGroup, ok := consortiumsGroup.Groups[consortium]
+	if !ok {
+		return nil, fmt.Errorf("consortium %s does not exist", consortium)
+	}
+	if _, ok := consortiumGroup.Groups[org.Name]; ok {
+		return nil, fmt.Errorf("org %s is already a member of consortium %s", org.Name, consortium)
+	}
+
+	mspConfig, err := msp.GetVerifyingMspConfig(org.MSPDir, org.BCCSP, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading MSP configuration for org %s: %s", org.Name, err)
+	}
+
+	template := configtx.NewModPolicySettingTemplate(configvaluesmsp.AdminsPolicyKey,
+		configtx.NewSimpleTemplate(configvaluesmsp.TemplateGroupMSPWithAdminRolePrincipal(
+			[]string{config.ConsortiumsGroupKey, consortium, org.Name}, mspConfig,
+			org.AdminPrincipal == genesisconfig.AdminRoleAdminPrincipal,
+		)),
+	)
+	return cu.configUpdateEnvelope(current, template)
+}
+
+// UpdateOrgMSP returns a ConfigUpdateEnvelope which rotates the MSP configuration of org to org's
+// current MSPDir. path names the group org belongs to, not org itself (e.g.
+// []string{config.OrdererGroupKey} or []string{config.ConsortiumsGroupKey, "SampleConsortium"}),
+// and org.Name must already be a sub-group of it.
+func (cu *ConfigUpdater) UpdateOrgMSP(current *cb.Config, path []string, org *genesisconfig.Organization) (*cb.ConfigUpdateEnvelope, error) {
+	group := current.ChannelGroup
+	for _, key := range path {
+		next, ok := group.Groups[key]
+		if !ok {
+			return nil, fmt.Errorf("path %v does not exist in current config", path)
+		}
+		group = next
+	}
+	if _, ok := group.Groups[org.Name]; !ok {
+		return nil, fmt.Errorf("org %s does not exist at path %v", org.Name, path)
+	}
+
+	mspConfig, err := msp.GetVerifyingMspConfig(org.MSPDir, org.BCCSP, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading MSP configuration for org %s: %s", org.Name, err)
+	}
+
+	template := configtx.NewModPolicySettingTemplate(configvaluesmsp.AdminsPolicyKey,
+		configtx.NewSimpleTemplate(configvaluesmsp.TemplateGroupMSPWithAdminRolePrincipal(
+			append(append([]string{}, path...), org.Name), mspConfig,
+			org.AdminPrincipal == genesisconfig.AdminRoleAdminPrincipal,
+		)),
+	)
+	return cu.configUpdateEnvelope(current, template)
+}
+
+// configUpdateEnvelope builds a ConfigUpdateEnvelope for applying the groups/values produced by
+// template on top of current. The read set is a snapshot of the unmodified current config, so
+// the update is rejected if anything it depends on has changed underneath it; the write set is
+// the same snapshot with template's groups merged in by mergeConfigGroup: a group whose own
+// values the template changes has its version bumped by one and its mod_policy set to Admins,
+// and a group the template creates carries the Admins mod_policy the template gave it.
+func (cu *ConfigUpdater) configUpdateEnvelope(current *cb.Config, template configtx.Template) (*cb.ConfigUpdateEnvelope, error) {
+	delta, err := template.Envelope(cu.channelID)
+	if err != nil {
+		return nil, fmt.Errorf("error computing config update template: %s", err)
+	}
+
+	deltaUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(delta.ConfigUpdate, deltaUpdate); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config update template: %s", err)
+	}
+
+	readSet := proto.Clone(current.ChannelGroup).(*cb.ConfigGroup)
+	writeSet := proto.Clone(current.ChannelGroup).(*cb.ConfigGroup)
+
+	if err := mergeConfigGroup(writeSet, deltaUpdate.WriteSet); err != nil {
+		return nil, fmt.Errorf("error merging config update template: %s", err)
+	}
+
+	configUpdate := &cb.ConfigUpdate{
+		ChannelId: cu.channelID,
+		ReadSet:   readSet,
+		WriteSet:  writeSet,
+	}
+
+	return &cb.ConfigUpdateEnvelope{ConfigUpdate: utils.MarshalOrPanic(configUpdate)}, nil
+}
+
+// mergeConfigGroup recursively merges the values and sub-groups present in delta into dst. Only
+// a group whose own Values are directly changed by delta has its version bumped and its
+// mod_policy forced to Admins; passing through an unchanged ancestor to reach a nested group
+// leaves that ancestor's version and mod_policy untouched, so an operator's existing settings on
+// it are not silently overwritten. A group delta creates from scratch is taken as-is, since the
+// per-call template that produced it (via NewModPolicySettingTemplate) already set its version
+// and mod_policy.
+func mergeConfigGroup(dst, delta *cb.ConfigGroup) error {
+	if delta == nil {
+		return nil
+	}
+
+	if len(delta.Values) > 0 {
+		if dst.Values == nil {
+			dst.Values = map[string]*cb.ConfigValue{}
+		}
+		for key, value := range delta.Values {
+			dst.Values[key] = value
+		}
+		dst.Version++
+		dst.ModPolicy = configvaluesmsp.AdminsPolicyKey
+	}
+
+	for key, deltaGroup := range delta.Groups {
+		if dst.Groups == nil {
+			dst.Groups = map[string]*cb.ConfigGroup{}
+		}
+		dstGroup, ok := dst.Groups[key]
+		if !ok {
+			dst.Groups[key] = deltaGroup
+			continue
+		}
+		if err := mergeConfigGroup(dstGroup, deltaGroup); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
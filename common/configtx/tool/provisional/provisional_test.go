@@ -0,0 +1,187 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisional
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func unmarshalConfigUpdate(t *testing.T, env *cb.Envelope) *cb.ConfigUpdate {
+	t.Helper()
+
+	payload, err := utils.ExtractPayload(env)
+	if err != nil {
+		t.Fatalf("unexpected error extracting payload: %s", err)
+	}
+
+	configUpdateEnvelope := &cb.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configUpdateEnvelope); err != nil {
+		t.Fatalf("unexpected error unmarshaling config update envelope: %s", err)
+	}
+
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate); err != nil {
+		t.Fatalf("unexpected error unmarshaling config update: %s", err)
+	}
+
+	return configUpdate
+}
+
+func TestChannelCreationTxRequiresChannelID(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{Consortiums: map[string]*genesisconfig.Consortium{"SampleConsortium": {}}}}
+
+	_, err := bs.ChannelCreationTx("", "SampleConsortium")
+	if err == nil {
+		t.Fatalf("expected an error for an empty channel ID")
+	}
+}
+
+func TestChannelCreationTxRequiresConsortium(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{Consortiums: map[string]*genesisconfig.Consortium{"SampleConsortium": {}}}}
+
+	_, err := bs.ChannelCreationTx("mychannel", "")
+	if err == nil {
+		t.Fatalf("expected an error for an empty consortium")
+	}
+}
+
+func TestChannelCreationTxUnknownConsortium(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{Consortiums: map[string]*genesisconfig.Consortium{"SampleConsortium": {}}}}
+
+	_, err := bs.ChannelCreationTx("mychannel", "NotAConsortium")
+	if err == nil {
+		t.Fatalf("expected an error for a consortium not present in the profile")
+	}
+}
+
+func TestChannelCreationTxNoConsortiums(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{}}
+
+	_, err := bs.ChannelCreationTx("mychannel", "SampleConsortium")
+	if err == nil {
+		t.Fatalf("expected an error when the profile defines no consortiums at all")
+	}
+}
+
+func TestChannelCreationTxSetsConsortium(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{Consortiums: map[string]*genesisconfig.Consortium{"SampleConsortium": {}}}}
+
+	env, err := bs.ChannelCreationTx("mychannel", "SampleConsortium")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	configUpdate := unmarshalConfigUpdate(t, env)
+
+	value, ok := configUpdate.WriteSet.Values[config.ConsortiumKey]
+	if !ok {
+		t.Fatalf("expected a consortium value in the write set")
+	}
+	consortium := &cb.Consortium{}
+	if err := proto.Unmarshal(value.Value, consortium); err != nil {
+		t.Fatalf("unexpected error unmarshaling consortium: %s", err)
+	}
+	if consortium.Name != "SampleConsortium" {
+		t.Fatalf("expected consortium name SampleConsortium, got %s", consortium.Name)
+	}
+}
+
+func TestAnchorPeersUpdateRequiresChannelID(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{
+		Application: &genesisconfig.Application{
+			Organizations: []*genesisconfig.Organization{{Name: "Org1", AnchorPeers: []*genesisconfig.AnchorPeer{{Host: "host1", Port: 7051}}}},
+		},
+	}}
+
+	_, err := bs.AnchorPeersUpdate("", "Org1")
+	if err == nil {
+		t.Fatalf("expected an error for an empty channel ID")
+	}
+}
+
+func TestAnchorPeersUpdateNoApplication(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{}}
+
+	_, err := bs.AnchorPeersUpdate("mychannel", "Org1")
+	if err == nil {
+		t.Fatalf("expected an error when the profile has no application section")
+	}
+}
+
+func TestAnchorPeersUpdateUnknownOrg(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{
+		Application: &genesisconfig.Application{
+			Organizations: []*genesisconfig.Organization{{Name: "Org1", AnchorPeers: []*genesisconfig.AnchorPeer{{Host: "host1", Port: 7051}}}},
+		},
+	}}
+
+	_, err := bs.AnchorPeersUpdate("mychannel", "Org2")
+	if err == nil {
+		t.Fatalf("expected an error for an org not present in the application profile")
+	}
+}
+
+func TestAnchorPeersUpdateSetsHostPort(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{
+		Application: &genesisconfig.Application{
+			Organizations: []*genesisconfig.Organization{{Name: "Org1", AnchorPeers: []*genesisconfig.AnchorPeer{{Host: "host1", Port: 7051}}}},
+		},
+	}}
+
+	env, err := bs.AnchorPeersUpdate("mychannel", "Org1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	configUpdate := unmarshalConfigUpdate(t, env)
+
+	orgGroup, ok := configUpdate.WriteSet.Groups[config.ApplicationGroupKey].Groups["Org1"]
+	if !ok {
+		t.Fatalf("expected an Org1 group in the write set")
+	}
+	value, ok := orgGroup.Values[config.AnchorPeersKey]
+	if !ok {
+		t.Fatalf("expected an anchor peers value for Org1")
+	}
+	anchorPeers := &pb.AnchorPeers{}
+	if err := proto.Unmarshal(value.Value, anchorPeers); err != nil {
+		t.Fatalf("unexpected error unmarshaling anchor peers: %s", err)
+	}
+	if len(anchorPeers.AnchorPeers) != 1 || anchorPeers.AnchorPeers[0].Host != "host1" || anchorPeers.AnchorPeers[0].Port != 7051 {
+		t.Fatalf("expected anchor peer host1:7051, got %v", anchorPeers.AnchorPeers)
+	}
+}
+
+func TestAnchorPeersUpdateNoAnchorPeers(t *testing.T) {
+	bs := &bootstrapper{conf: &genesisconfig.Profile{
+		Application: &genesisconfig.Application{
+			Organizations: []*genesisconfig.Organization{{Name: "Org1"}},
+		},
+	}}
+
+	_, err := bs.AnchorPeersUpdate("mychannel", "Org1")
+	if err == nil {
+		t.Fatalf("expected an error for an org with no anchor peers configured")
+	}
+}
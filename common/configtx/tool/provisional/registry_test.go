@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisional
+
+import (
+	"testing"
+
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+)
+
+func TestRegisterConsensusTypeDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected RegisterConsensusType to panic on a name that is already registered")
+		}
+	}()
+
+	RegisterConsensusType(ConsensusTypeSolo, soloConsenterConfigGroups)
+}
+
+func TestNewWithErrorUnknownOrdererType(t *testing.T) {
+	profile := &genesisconfig.Profile{
+		Orderer: &genesisconfig.Orderer{OrdererType: "not-a-registered-type"},
+	}
+
+	_, err := NewWithError(profile)
+	if err == nil {
+		t.Fatalf("expected an error for an unregistered orderer type")
+	}
+}
+
+func TestNewPanicsOnUnknownOrdererType(t *testing.T) {
+	profile := &genesisconfig.Profile{
+		Orderer: &genesisconfig.Orderer{OrdererType: "not-a-registered-type"},
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected New to panic on an unregistered orderer type")
+		}
+	}()
+
+	New(profile)
+}
+
+func TestNewWithErrorRegisteredType(t *testing.T) {
+	profile := &genesisconfig.Profile{
+		Orderer: &genesisconfig.Orderer{OrdererType: ConsensusTypeSolo},
+	}
+
+	if _, err := NewWithError(profile); err != nil {
+		t.Fatalf("unexpected error for a registered orderer type: %s", err)
+	}
+}